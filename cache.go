@@ -0,0 +1,215 @@
+package view
+
+import (
+	"io/fs"
+	"strings"
+	"text/template/parse"
+	"time"
+)
+
+// templateFile is a single template file read off disk (or whatever fsys
+// backs v), along with the mtime it was read at.
+type templateFile struct {
+	name    string
+	modTime time.Time
+	body    string
+}
+
+// cacheEntry holds the last parsed state of a single template file, used by
+// syncTemplates to tell whether it needs to be reparsed and, if not, to
+// splice its already-parsed tree into the next template set for free.
+type cacheEntry struct {
+	modTime time.Time
+	tree    *parse.Tree
+}
+
+// readTemplateFiles walks v.fsys under v.path and reads every file whose
+// name has the v.tmplExt suffix. Unlike the original filepath.Walk-based
+// implementation, a walk error aborts immediately instead of being logged
+// and ignored, which could previously leave v.tmpls silently stale.
+func (v *Views) readTemplateFiles() ([]templateFile, error) {
+	var files []templateFile
+
+	err := fs.WalkDir(v.fsys, v.path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, v.tmplExt) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		b, err := fs.ReadFile(v.fsys, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, templateFile{v.TemplateName(path), info.ModTime(), string(b)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// buildTemplate parses files into a fresh template set from scratch,
+// registering v.funcs and any function-style templates discovered among
+// files (see discoverTmplFuncs). It returns the parsed set, the func names
+// of the function-style templates it registered (v.funcs' names are not
+// included), and a cache entry per file so that a later syncTemplates
+// can reuse unchanged files' parse trees instead of reparsing them.
+func buildTemplate(v *Views, files []templateFile) (Template, []string, map[string]cacheEntry, error) {
+	tmplFuncs, err := discoverTmplFuncs(v, files)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	t := newRootTemplate(v.mode).Delims(v.ldelim, v.rdelim)
+	if v.funcs != nil {
+		t = t.Funcs(v.funcs)
+	}
+
+	var fnNames []string
+	if len(tmplFuncs) > 0 {
+		t = t.Funcs(tmplFuncMap(v.mode, t, tmplFuncs))
+		for _, tf := range tmplFuncs {
+			fnNames = append(fnNames, tf.fn)
+		}
+	}
+
+	cache := make(map[string]cacheEntry, len(files))
+	for _, f := range files {
+		parsed, err := t.New(f.name).Parse(f.body)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		cache[f.name] = cacheEntry{f.modTime, parsed.Tree()}
+	}
+
+	return t, fnNames, cache, nil
+}
+
+// syncTemplates is the reload-mode counterpart to ParseTemplates. Instead
+// of always rereading and reparsing every file, it stats the template tree
+// and only reparses the files whose ModTime changed since the last sync;
+// unchanged files have their already-parsed tree spliced into the new set
+// via AddParseTree. This turns per-request dev-mode rendering from
+// O(all files) into O(changed files).
+//
+// A fresh template set is built on every change (html/template forbids
+// calling Parse again on a set that has already been Executed), and a full
+// rebuild via buildTemplate happens whenever the number of files changes, or
+// a changed file alters the set of function-style templates (see
+// discoverTmplFuncs), since a func, once registered, cannot be removed
+// from a template set either.
+func (v *Views) syncTemplates() error {
+	v.mu.Lock()
+	cache := v.cache
+	fnNames := v.fnNames
+	v.mu.Unlock()
+
+	files, err := v.readTemplateFiles()
+	if err != nil {
+		return err
+	}
+
+	changed := len(files) != len(cache)
+	for _, f := range files {
+		e, ok := cache[f.name]
+		if !ok || !e.modTime.Equal(f.modTime) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	tmplFuncs, err := discoverTmplFuncs(v, files)
+	if err != nil {
+		return err
+	}
+	var newFnNames []string
+	for _, tf := range tmplFuncs {
+		newFnNames = append(newFnNames, tf.fn)
+	}
+
+	var (
+		t        Template
+		newCache map[string]cacheEntry
+	)
+	if len(files) == len(cache) && sameStrings(fnNames, newFnNames) {
+		t, newCache, err = v.spliceTemplate(files, cache, tmplFuncs)
+	} else {
+		t, newFnNames, newCache, err = buildTemplate(v, files)
+	}
+	if err != nil {
+		return err
+	}
+	pristine, err := t.Clone()
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.tmpls = t
+	v.pristine = pristine
+	v.cache = newCache
+	v.fnNames = newFnNames
+	v.mu.Unlock()
+	return nil
+}
+
+// spliceTemplate builds a fresh template set where files whose ModTime
+// hasn't changed reuse their cached parse tree (via AddParseTree) and only
+// the remaining files are actually reparsed.
+func (v *Views) spliceTemplate(files []templateFile, cache map[string]cacheEntry, tmplFuncs []tmplFunc) (Template, map[string]cacheEntry, error) {
+	t := newRootTemplate(v.mode).Delims(v.ldelim, v.rdelim)
+	if v.funcs != nil {
+		t = t.Funcs(v.funcs)
+	}
+	if len(tmplFuncs) > 0 {
+		t = t.Funcs(tmplFuncMap(v.mode, t, tmplFuncs))
+	}
+
+	newCache := make(map[string]cacheEntry, len(files))
+	for _, f := range files {
+		if e, ok := cache[f.name]; ok && e.modTime.Equal(f.modTime) {
+			if _, err := t.AddParseTree(f.name, e.tree); err != nil {
+				return nil, nil, err
+			}
+			newCache[f.name] = e
+			continue
+		}
+
+		parsed, err := t.New(f.name).Parse(f.body)
+		if err != nil {
+			return nil, nil, err
+		}
+		newCache[f.name] = cacheEntry{f.modTime, parsed.Tree()}
+	}
+
+	return t, newCache, nil
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		seen[s]--
+	}
+	for _, n := range seen {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
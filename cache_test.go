@@ -0,0 +1,105 @@
+package view
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTmpl(t *testing.T, dir, name, body string, mtime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("WriteFile(%s): %v", path, err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("Chtimes(%s): %v", path, err)
+	}
+}
+
+func TestSyncTemplatesNoopWhenUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Now()
+	writeTmpl(t, dir, "page.tmpl", `{{define "page"}}hi{{end}}`, t0)
+
+	v, err := NewViews(dir, ".tmpl", true)
+	if err != nil {
+		t.Fatalf("NewViews: %v", err)
+	}
+
+	before := v.tmpls
+	if err := v.syncTemplates(); err != nil {
+		t.Fatalf("syncTemplates: %v", err)
+	}
+	if v.tmpls != before {
+		t.Error("syncTemplates rebuilt an unchanged template set")
+	}
+}
+
+func TestSyncTemplatesSplicesUnchangedFile(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Now()
+	writeTmpl(t, dir, "a.tmpl", `{{define "a"}}a{{end}}`, t0)
+	writeTmpl(t, dir, "b.tmpl", `{{define "b"}}b{{end}}`, t0)
+
+	v, err := NewViews(dir, ".tmpl", true)
+	if err != nil {
+		t.Fatalf("NewViews: %v", err)
+	}
+	aTree := v.cache["a"].tree
+
+	// Only b changes; a's cached tree should be spliced in unchanged.
+	writeTmpl(t, dir, "b.tmpl", `{{define "b"}}b2{{end}}`, t0.Add(time.Second))
+	if err := v.syncTemplates(); err != nil {
+		t.Fatalf("syncTemplates: %v", err)
+	}
+
+	if v.cache["a"].tree != aTree {
+		t.Error("syncTemplates reparsed an unchanged file instead of splicing its cached tree")
+	}
+}
+
+func TestSyncTemplatesRebuildsOnAddedFile(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Now()
+	writeTmpl(t, dir, "a.tmpl", `{{define "a"}}a{{end}}`, t0)
+
+	v, err := NewViews(dir, ".tmpl", true)
+	if err != nil {
+		t.Fatalf("NewViews: %v", err)
+	}
+
+	writeTmpl(t, dir, "b.tmpl", `{{define "b"}}b{{end}}`, t0)
+	if err := v.syncTemplates(); err != nil {
+		t.Fatalf("syncTemplates: %v", err)
+	}
+	if _, ok := v.cache["b"]; !ok {
+		t.Error("syncTemplates did not pick up a newly added file")
+	}
+}
+
+func TestSyncTemplatesRebuildsOnFuncSetChange(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Now()
+	writeTmpl(t, dir, "a.tmpl", `{{define "a"}}a{{end}}`, t0)
+	writeTmpl(t, dir, "link.tmpl", `{{define "link"}}plain{{end}}`, t0)
+
+	v, err := NewViews(dir, ".tmpl", true)
+	if err != nil {
+		t.Fatalf("NewViews: %v", err)
+	}
+	aTree := v.cache["a"].tree
+
+	// a is untouched, but link.tmpl turns into a function-style template,
+	// which changes the registered func set and must force a full rebuild
+	// rather than a splice, even though the file count is unchanged.
+	writeTmpl(t, dir, "link.tmpl", `{{define "link url"}}{{.url}}{{end}}`, t0.Add(time.Second))
+	if err := v.syncTemplates(); err != nil {
+		t.Fatalf("syncTemplates: %v", err)
+	}
+
+	if v.cache["a"].tree == aTree {
+		t.Error("syncTemplates spliced instead of rebuilding after the func set changed")
+	}
+}
@@ -0,0 +1,114 @@
+package view
+
+import (
+	"io"
+	"strings"
+)
+
+// SetLayoutsDir sets the template-name prefix (relative to the template
+// root) that ExecuteLayout requires its layout argument to live under,
+// e.g. "layouts" so only names like "layouts/base" are accepted.
+// Defaults to "layouts". Safe for concurrent access.
+func (v *Views) SetLayoutsDir(dir string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.layoutsDir = dir
+}
+
+// SetPartialsDir sets the template-name prefix (relative to the template
+// root) that ExecuteLayout refuses as a page argument, e.g. "partials" so
+// a name like "partials/nav" can be rendered as a shared fragment from a
+// layout but can't itself be passed to ExecuteLayout as the page.
+// Defaults to "partials". Safe for concurrent access.
+func (v *Views) SetPartialsDir(dir string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.partialsDir = dir
+}
+
+// ExecuteLayout renders layout with page's body substituted for the
+// layout's {{block "content" .}}. Both layout and page are full template
+// names as returned by TemplateName, e.g. "layouts/base" and
+// "pages/home/index".
+//
+// layout must live under the layouts dir (see SetLayoutsDir) and page must
+// not live under the layouts or partials dir (see SetPartialsDir): files
+// under those subfolders are shared chrome and partials, not standalone
+// pages, so ExecuteLayout rejects passing one as page with a
+// *LayoutConventionError.
+//
+// Since layout and page are associated per call rather than at parse time,
+// this removes the boilerplate of manually cloning the parsed template set
+// per request to compose a layout with a page.
+func (v *Views) ExecuteLayout(w io.Writer, layout, page string, data interface{}) error {
+	if v.reload {
+		if err := v.syncTemplates(); err != nil {
+			return err
+		}
+	}
+
+	v.mu.Lock()
+	pristine := v.pristine
+	layoutsDir := v.layoutsDir
+	partialsDir := v.partialsDir
+	v.mu.Unlock()
+
+	if underDir(page, layoutsDir) || underDir(page, partialsDir) {
+		return &LayoutConventionError{Name: page, Reason: "is not a page: it lives under the layouts or partials dir"}
+	}
+	if !underDir(layout, layoutsDir) {
+		return &LayoutConventionError{Name: layout, Reason: "is not a layout: it does not live under the layouts dir"}
+	}
+
+	pageTmpl := pristine.Lookup(page)
+	if pageTmpl == nil {
+		return &TemplateNotFoundError{Name: page}
+	}
+	layoutTmpl := pristine.Lookup(layout)
+	if layoutTmpl == nil {
+		return &TemplateNotFoundError{Name: layout}
+	}
+
+	// Clone pristine, not v.tmpls: v.tmpls may already have been passed to
+	// Execute/ExecuteTemplate by the time ExecuteLayout runs, and
+	// html/template permanently refuses to Clone a template set once it
+	// has executed. pristine is never itself executed, only cloned, so it
+	// stays clonable for the lifetime of v.
+	clone, err := pristine.Clone()
+	if err != nil {
+		return err
+	}
+	if _, err := clone.AddParseTree("content", pageTmpl.Tree()); err != nil {
+		return err
+	}
+
+	return clone.ExecuteTemplate(w, layout, data)
+}
+
+// underDir reports whether name lives under dir, treated as a template-name
+// prefix (e.g. "layouts/base" is under "layouts", "layouts" itself is too).
+func underDir(name, dir string) bool {
+	return dir != "" && (name == dir || strings.HasPrefix(name, dir+"/"))
+}
+
+// TemplateNotFoundError is returned by ExecuteLayout when the requested
+// layout or page template does not exist in the parsed set.
+type TemplateNotFoundError struct {
+	Name string
+}
+
+func (e *TemplateNotFoundError) Error() string {
+	return "view: template not found: " + e.Name
+}
+
+// LayoutConventionError is returned by ExecuteLayout when layout or page
+// doesn't respect the layouts/partials convention (see SetLayoutsDir,
+// SetPartialsDir).
+type LayoutConventionError struct {
+	Name   string
+	Reason string
+}
+
+func (e *LayoutConventionError) Error() string {
+	return "view: " + e.Name + " " + e.Reason
+}
@@ -0,0 +1,86 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestViews(t *testing.T) *Views {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"layouts/base.tmpl": {Data: []byte(`{{define "layouts/base"}}<html>{{block "content" .}}{{end}}</html>{{end}}`)},
+		"pages/about.tmpl":  {Data: []byte(`{{define "pages/about"}}about{{end}}`)},
+	}
+	v, err := NewViewsFS(fsys, ".", ".tmpl", false)
+	if err != nil {
+		t.Fatalf("NewViewsFS: %v", err)
+	}
+	return v
+}
+
+// TestExecuteLayoutAfterExecute guards against a regression where
+// ExecuteLayout cloned v.tmpls directly: in html/template mode, once any
+// direct Execute/ExecuteTemplate call had run against v.tmpls, the whole
+// set was marked "executed" and Clone permanently failed on every later
+// ExecuteLayout call.
+func TestExecuteLayoutAfterExecute(t *testing.T) {
+	v := newTestViews(t)
+
+	var buf bytes.Buffer
+	if err := v.ExecuteTemplate(&buf, "pages/about", nil); err != nil {
+		t.Fatalf("ExecuteTemplate: %v", err)
+	}
+
+	buf.Reset()
+	if err := v.ExecuteLayout(&buf, "layouts/base", "pages/about", nil); err != nil {
+		t.Fatalf("ExecuteLayout after ExecuteTemplate: %v", err)
+	}
+	if got, want := buf.String(), "<html>about</html>"; got != want {
+		t.Errorf("ExecuteLayout output = %q, want %q", got, want)
+	}
+}
+
+func TestExecuteLayoutUnknownPage(t *testing.T) {
+	v := newTestViews(t)
+
+	var buf bytes.Buffer
+	err := v.ExecuteLayout(&buf, "layouts/base", "pages/missing", nil)
+	if _, ok := err.(*TemplateNotFoundError); !ok {
+		t.Fatalf("ExecuteLayout error = %v, want *TemplateNotFoundError", err)
+	}
+}
+
+// TestExecuteLayoutUnknownLayout guards against a regression where an
+// unknown layout fell through to ExecuteTemplate and surfaced as a raw
+// html/template error instead of the same *TemplateNotFoundError an
+// unknown page gets.
+func TestExecuteLayoutUnknownLayout(t *testing.T) {
+	v := newTestViews(t)
+
+	var buf bytes.Buffer
+	err := v.ExecuteLayout(&buf, "layouts/missing", "pages/about", nil)
+	if _, ok := err.(*TemplateNotFoundError); !ok {
+		t.Fatalf("ExecuteLayout error = %v, want *TemplateNotFoundError", err)
+	}
+}
+
+func TestExecuteLayoutRejectsPageUnderLayoutsDir(t *testing.T) {
+	v := newTestViews(t)
+
+	var buf bytes.Buffer
+	err := v.ExecuteLayout(&buf, "layouts/base", "layouts/base", nil)
+	if _, ok := err.(*LayoutConventionError); !ok {
+		t.Fatalf("ExecuteLayout error = %v, want *LayoutConventionError", err)
+	}
+}
+
+func TestExecuteLayoutRejectsLayoutOutsideLayoutsDir(t *testing.T) {
+	v := newTestViews(t)
+
+	var buf bytes.Buffer
+	err := v.ExecuteLayout(&buf, "pages/about", "pages/about", nil)
+	if _, ok := err.(*LayoutConventionError); !ok {
+		t.Fatalf("ExecuteLayout error = %v, want *LayoutConventionError", err)
+	}
+}
@@ -0,0 +1,165 @@
+package view
+
+import (
+	"html/template"
+	"io"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// mode selects which standard library template package a Views instance is
+// built on.
+type mode int
+
+const (
+	htmlMode mode = iota
+	textMode
+)
+
+// Template abstracts over html/template.Template and text/template.Template
+// so that the rest of this package can parse and render a set of named
+// templates without caring which escaping mode it was built in. NewViews
+// (and friends) build an html-mode Template; NewViewsText (and friends)
+// build a text-mode one.
+type Template interface {
+	New(name string) Template
+	Delims(left, right string) Template
+	Funcs(funcMap map[string]interface{}) Template
+	Parse(text string) (Template, error)
+	AddParseTree(name string, tree *parse.Tree) (Template, error)
+	Clone() (Template, error)
+	Lookup(name string) Template
+	Tree() *parse.Tree
+	Templates() []Template
+	Execute(w io.Writer, data interface{}) error
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+// newRootTemplate creates an empty, named "all" template in mode m. It
+// applies no delimiters; callers set them with Delims.
+func newRootTemplate(m mode) Template {
+	switch m {
+	case textMode:
+		return textTmpl{texttemplate.New("all")}
+	default:
+		return htmlTmpl{template.New("all")}
+	}
+}
+
+// htmlTmpl adapts *html/template.Template to Template.
+type htmlTmpl struct{ t *template.Template }
+
+func (a htmlTmpl) New(name string) Template    { return htmlTmpl{a.t.New(name)} }
+func (a htmlTmpl) Delims(l, r string) Template { return htmlTmpl{a.t.Delims(l, r)} }
+
+func (a htmlTmpl) Funcs(fm map[string]interface{}) Template {
+	return htmlTmpl{a.t.Funcs(template.FuncMap(fm))}
+}
+
+func (a htmlTmpl) Parse(text string) (Template, error) {
+	t, err := a.t.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return htmlTmpl{t}, nil
+}
+
+func (a htmlTmpl) AddParseTree(name string, tree *parse.Tree) (Template, error) {
+	t, err := a.t.AddParseTree(name, tree)
+	if err != nil {
+		return nil, err
+	}
+	return htmlTmpl{t}, nil
+}
+
+func (a htmlTmpl) Clone() (Template, error) {
+	t, err := a.t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return htmlTmpl{t}, nil
+}
+
+func (a htmlTmpl) Lookup(name string) Template {
+	t := a.t.Lookup(name)
+	if t == nil {
+		return nil
+	}
+	return htmlTmpl{t}
+}
+
+func (a htmlTmpl) Tree() *parse.Tree { return a.t.Tree }
+
+func (a htmlTmpl) Templates() []Template {
+	ts := a.t.Templates()
+	out := make([]Template, len(ts))
+	for i, t := range ts {
+		out[i] = htmlTmpl{t}
+	}
+	return out
+}
+
+func (a htmlTmpl) Execute(w io.Writer, data interface{}) error { return a.t.Execute(w, data) }
+
+func (a htmlTmpl) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	return a.t.ExecuteTemplate(w, name, data)
+}
+
+// textTmpl adapts *text/template.Template to Template.
+type textTmpl struct{ t *texttemplate.Template }
+
+func (a textTmpl) New(name string) Template    { return textTmpl{a.t.New(name)} }
+func (a textTmpl) Delims(l, r string) Template { return textTmpl{a.t.Delims(l, r)} }
+
+func (a textTmpl) Funcs(fm map[string]interface{}) Template {
+	return textTmpl{a.t.Funcs(texttemplate.FuncMap(fm))}
+}
+
+func (a textTmpl) Parse(text string) (Template, error) {
+	t, err := a.t.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return textTmpl{t}, nil
+}
+
+func (a textTmpl) AddParseTree(name string, tree *parse.Tree) (Template, error) {
+	t, err := a.t.AddParseTree(name, tree)
+	if err != nil {
+		return nil, err
+	}
+	return textTmpl{t}, nil
+}
+
+func (a textTmpl) Clone() (Template, error) {
+	t, err := a.t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return textTmpl{t}, nil
+}
+
+func (a textTmpl) Lookup(name string) Template {
+	t := a.t.Lookup(name)
+	if t == nil {
+		return nil
+	}
+	return textTmpl{t}
+}
+
+func (a textTmpl) Tree() *parse.Tree { return a.t.Tree }
+
+func (a textTmpl) Templates() []Template {
+	ts := a.t.Templates()
+	out := make([]Template, len(ts))
+	for i, t := range ts {
+		out[i] = textTmpl{t}
+	}
+	return out
+}
+
+func (a textTmpl) Execute(w io.Writer, data interface{}) error { return a.t.Execute(w, data) }
+
+func (a textTmpl) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	return a.t.ExecuteTemplate(w, name, data)
+}
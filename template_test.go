@@ -0,0 +1,68 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+)
+
+func executeString(t Template, name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	if name == "" {
+		err = t.Execute(&buf, data)
+	} else {
+		err = t.ExecuteTemplate(&buf, name, data)
+	}
+	return buf.String(), err
+}
+
+// TestTemplateExecuteEscaping checks the core contract distinguishing the
+// two Template implementations: htmlTmpl (html/template) escapes values
+// passed through it, textTmpl (text/template) does not.
+func TestTemplateExecuteEscaping(t *testing.T) {
+	const data = `<script>`
+
+	html, err := newRootTemplate(htmlMode).Parse(`{{.}}`)
+	if err != nil {
+		t.Fatalf("html Parse: %v", err)
+	}
+	got, err := executeString(html, "", data)
+	if err != nil {
+		t.Fatalf("html Execute: %v", err)
+	}
+	if want := "&lt;script&gt;"; got != want {
+		t.Errorf("html mode output = %q, want %q", got, want)
+	}
+
+	text, err := newRootTemplate(textMode).Parse(`{{.}}`)
+	if err != nil {
+		t.Fatalf("text Parse: %v", err)
+	}
+	got, err = executeString(text, "", data)
+	if err != nil {
+		t.Fatalf("text Execute: %v", err)
+	}
+	if got != data {
+		t.Errorf("text mode output = %q, want %q (should not be escaped)", got, data)
+	}
+}
+
+// TestTemplateExecuteTemplateDispatch checks that ExecuteTemplate runs the
+// named associated template rather than the root, in both modes.
+func TestTemplateExecuteTemplateDispatch(t *testing.T) {
+	for _, m := range []mode{htmlMode, textMode} {
+		root := newRootTemplate(m)
+		sub, err := root.New("greeting").Parse(`hello {{.}}`)
+		if err != nil {
+			t.Fatalf("mode %v: Parse: %v", m, err)
+		}
+
+		got, err := executeString(sub, "greeting", "world")
+		if err != nil {
+			t.Fatalf("mode %v: ExecuteTemplate: %v", m, err)
+		}
+		if want := "hello world"; got != want {
+			t.Errorf("mode %v: ExecuteTemplate output = %q, want %q", m, got, want)
+		}
+	}
+}
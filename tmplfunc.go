@@ -0,0 +1,198 @@
+package view
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// defineRe matches the name argument of a {{define "..."}} action, which is
+// where function-style templates (see tmplFuncParams) are declared.
+var defineRe = regexp.MustCompile(`\{\{-?\s*define\s+"([^"]*)"\s*-?\}\}`)
+
+var paramNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// tmplFuncParam describes a single named parameter of a function-style
+// template, e.g. the `url` in a template defined as `link url text`.
+type tmplFuncParam struct {
+	name     string
+	optional bool
+	variadic bool
+}
+
+// tmplFunc describes a template invocable as a function, e.g. a template
+// defined as `{{define "link url text"}}`. name is the template's full
+// define name ("link url text"), fn is the func name it is invoked under
+// ("link"), and params are its named, ordered parameters.
+type tmplFunc struct {
+	name   string
+	fn     string
+	params []tmplFuncParam
+}
+
+// parseTmplFunc parses a {{define}} name such as "link url text" into its
+// function name and parameters. It returns ok == false for plain template
+// names that carry no parameter list (the common case).
+func parseTmplFunc(name string) (tf tmplFunc, ok bool, err error) {
+	fields := strings.Fields(name)
+	if len(fields) < 2 {
+		return tmplFunc{}, false, nil
+	}
+
+	tf = tmplFunc{name: name, fn: fields[0]}
+	seen := make(map[string]bool, len(fields)-1)
+
+	for i, raw := range fields[1:] {
+		p := tmplFuncParam{name: raw}
+		if strings.HasSuffix(p.name, "...") {
+			p.variadic = true
+			p.name = strings.TrimSuffix(p.name, "...")
+			if i != len(fields)-2 {
+				return tmplFunc{}, false, fmt.Errorf("view: variadic parameter %q of %q must be last", raw, name)
+			}
+		} else if strings.HasSuffix(p.name, "?") {
+			p.optional = true
+			p.name = strings.TrimSuffix(p.name, "?")
+		}
+
+		if !paramNameRe.MatchString(p.name) {
+			return tmplFunc{}, false, fmt.Errorf("view: invalid parameter name %q in %q", raw, name)
+		}
+		if seen[p.name] {
+			return tmplFunc{}, false, fmt.Errorf("view: duplicate parameter %q in %q", p.name, name)
+		}
+		seen[p.name] = true
+
+		tf.params = append(tf.params, p)
+	}
+
+	return tf, true, nil
+}
+
+// discoverTmplFuncs finds the function-style templates actually declared
+// among files by parsing them, rather than pattern-matching their raw
+// source for {{define "..."}}. A naive regex over raw text also matches
+// defines written inside a {{/* ... */}} comment, which the real parser
+// never creates a template for; discoverTmplFuncs can't be fooled that way
+// since it asks the parsed result what templates exist.
+//
+// Go's parser rejects a call to a func that isn't registered yet, so a
+// single pass can't work: the names a file calls may be declared by
+// another file we haven't looked at. The first pass works around this by
+// registering a placeholder for every name defineRe finds in the raw
+// source (including ones inside comments - harmless, since nothing calls
+// a commented-out template) and parsing every file into a throwaway
+// template set under those placeholders. The second pass reads the
+// function-style templates that actually exist off that set's Templates,
+// which - unlike raw source - never contains a name from inside a
+// comment, and validates each with parseTmplFunc exactly as before.
+func discoverTmplFuncs(v *Views, files []templateFile) ([]tmplFunc, error) {
+	placeholders := make(map[string]interface{})
+	for _, f := range files {
+		for _, m := range defineRe.FindAllStringSubmatch(f.body, -1) {
+			// Validation is left to the second pass, so that an invalid
+			// define inside a comment can't fail the parse.
+			if fields := strings.Fields(m[1]); len(fields) >= 2 {
+				placeholders[fields[0]] = tmplFuncPlaceholder
+			}
+		}
+	}
+
+	t := newRootTemplate(v.mode).Delims(v.ldelim, v.rdelim)
+	if v.funcs != nil {
+		t = t.Funcs(v.funcs)
+	}
+	if len(placeholders) > 0 {
+		t = t.Funcs(placeholders)
+	}
+	for _, f := range files {
+		parsed, err := t.New(f.name).Parse(f.body)
+		if err != nil {
+			return nil, err
+		}
+		t = parsed
+	}
+
+	var funcs []tmplFunc
+	seen := make(map[string]bool)
+	for _, tmpl := range t.Templates() {
+		tree := tmpl.Tree()
+		if tree == nil || seen[tree.Name] {
+			continue
+		}
+		seen[tree.Name] = true
+
+		tf, ok, err := parseTmplFunc(tree.Name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			funcs = append(funcs, tf)
+		}
+	}
+	return funcs, nil
+}
+
+// tmplFuncPlaceholder stands in for a function-style template's real
+// invoker during discoverTmplFuncs' first, throwaway parse pass, so that
+// Go's "function not defined" check doesn't reject a call to a
+// function-style template declared in a file that hasn't been scanned
+// yet. It is never registered on a template set that is actually
+// executed.
+func tmplFuncPlaceholder(args ...interface{}) (interface{}, error) { return "", nil }
+
+// tmplFuncMap builds the func map that invokes each of funcs as a regular
+// template func, calling back into t (the template set being built) to
+// execute the underlying {{define}} block. In html mode the rendered
+// result is wrapped as template.HTML so it isn't escaped a second time by
+// the caller; in text mode there is no such concept, so it is returned as
+// a plain string.
+func tmplFuncMap(m mode, t Template, funcs []tmplFunc) map[string]interface{} {
+	fm := make(map[string]interface{}, len(funcs))
+	for _, tf := range funcs {
+		fm[tf.fn] = makeInvoker(m, t, tf)
+	}
+	return fm
+}
+
+// makeInvoker builds the func map entry for tf. The returned func collects
+// its positional arguments into a map keyed by parameter name and executes
+// tf.name against t. t is captured by reference to the template set being
+// built, which is fully populated by the time any template actually
+// executes.
+func makeInvoker(m mode, t Template, tf tmplFunc) func(...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		data := make(map[string]interface{}, len(tf.params))
+
+		i := 0
+		for _, p := range tf.params {
+			if p.variadic {
+				data[p.name] = args[i:]
+				i = len(args)
+				break
+			}
+			if i < len(args) {
+				data[p.name] = args[i]
+				i++
+			} else if p.optional {
+				data[p.name] = nil
+			} else {
+				return "", fmt.Errorf("view: template func %q: missing argument %q", tf.fn, p.name)
+			}
+		}
+		if i < len(args) {
+			return "", fmt.Errorf("view: template func %q: too many arguments", tf.fn)
+		}
+
+		var buf bytes.Buffer
+		if err := t.ExecuteTemplate(&buf, tf.name, data); err != nil {
+			return "", err
+		}
+		if m == textMode {
+			return buf.String(), nil
+		}
+		return template.HTML(buf.String()), nil
+	}
+}
@@ -0,0 +1,98 @@
+package view
+
+import "testing"
+
+func TestParseTmplFunc(t *testing.T) {
+	cases := []struct {
+		name    string
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "pages/about", wantOK: false},
+		{name: "link url text", wantOK: true},
+		{name: "link url? text", wantOK: true},
+		{name: "link url text...", wantOK: true},
+		{name: "link url... text", wantErr: true},
+		{name: "link url url", wantErr: true},
+		{name: "link 1url", wantErr: true},
+	}
+
+	for _, c := range cases {
+		_, ok, err := parseTmplFunc(c.name)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseTmplFunc(%q): want error, got nil", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTmplFunc(%q): unexpected error: %v", c.name, err)
+			continue
+		}
+		if ok != c.wantOK {
+			t.Errorf("parseTmplFunc(%q): ok = %v, want %v", c.name, ok, c.wantOK)
+		}
+	}
+}
+
+func TestParseTmplFuncParams(t *testing.T) {
+	tf, ok, err := parseTmplFunc("link url text? tags...")
+	if err != nil || !ok {
+		t.Fatalf("parseTmplFunc: ok=%v err=%v", ok, err)
+	}
+	if tf.fn != "link" {
+		t.Errorf("fn = %q, want %q", tf.fn, "link")
+	}
+	want := []tmplFuncParam{
+		{name: "url"},
+		{name: "text", optional: true},
+		{name: "tags", variadic: true},
+	}
+	if len(tf.params) != len(want) {
+		t.Fatalf("params = %+v, want %+v", tf.params, want)
+	}
+	for i, p := range want {
+		if tf.params[i] != p {
+			t.Errorf("params[%d] = %+v, want %+v", i, tf.params[i], p)
+		}
+	}
+}
+
+func TestDiscoverTmplFuncsIgnoresCommentedDefine(t *testing.T) {
+	v := &Views{mode: htmlMode}
+	files := []templateFile{
+		{name: "pages/ghost", body: `{{/* {{define "ghost url text"}}{{.url}}{{.text}}{{end}} */}}ghost page`},
+	}
+
+	funcs, err := discoverTmplFuncs(v, files)
+	if err != nil {
+		t.Fatalf("discoverTmplFuncs: %v", err)
+	}
+	for _, tf := range funcs {
+		if tf.fn == "ghost" {
+			t.Fatalf("discoverTmplFuncs registered %q from inside a template comment", tf.name)
+		}
+	}
+}
+
+func TestDiscoverTmplFuncsFindsRealDefine(t *testing.T) {
+	v := &Views{mode: htmlMode}
+	files := []templateFile{
+		{name: "partials/link", body: `{{define "link url text"}}<a href="{{.url}}">{{.text}}</a>{{end}}`},
+		{name: "pages/home", body: `{{define "pages/home"}}{{link "/" "home"}}{{end}}`},
+	}
+
+	funcs, err := discoverTmplFuncs(v, files)
+	if err != nil {
+		t.Fatalf("discoverTmplFuncs: %v", err)
+	}
+	var found bool
+	for _, tf := range funcs {
+		if tf.fn == "link" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("discoverTmplFuncs did not find link, got %+v", funcs)
+	}
+}
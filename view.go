@@ -9,36 +9,112 @@
 //
 // It allows safe reparsing of all files on each template execution to remove
 // the need for server restarts during development.
+//
+// Views parses with html/template by default; use NewViewsText (and its FS
+// and options variants) for text/template mode.
 package view
 
 import (
-	"html/template"
 	"io"
-	"io/ioutil"
-	"log"
+	"io/fs"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 )
 
 type Views struct {
 	reload bool
+	mode   mode
 
+	fsys    fs.FS
 	path    string
 	tmplExt string
-	tmpls   *template.Template
-	mu      *sync.Mutex
+	tmpls   Template
+	// pristine is a clone of tmpls taken right after parsing, before tmpls
+	// has ever been passed to Execute/ExecuteTemplate. ExecuteLayout clones
+	// pristine rather than tmpls, since html/template permanently refuses
+	// to Clone a template set once it has executed.
+	pristine Template
+	mu       *sync.Mutex
+
+	funcs          map[string]interface{}
+	ldelim, rdelim string
+
+	// layoutsDir and partialsDir are the template-name prefixes ExecuteLayout
+	// enforces its layouts/partials convention against (see SetLayoutsDir,
+	// SetPartialsDir): a page passed to ExecuteLayout may not live under
+	// either one, and a layout must live under layoutsDir.
+	layoutsDir, partialsDir string
+
+	cache   map[string]cacheEntry
+	fnNames []string
+
+	watcher *fsnotifyWatcher
 }
 
 // NewViews will parse all files initially. Returns parse errors.
+// It is a thin wrapper around NewViewsFS using os.DirFS(path) as the
+// filesystem. Templates are parsed in html/template mode; use NewViewsText
+// for text/template mode.
 func NewViews(path, tmplExt string, reload bool) (*Views, error) {
+	return NewViewsFS(os.DirFS(path), ".", tmplExt, reload)
+}
+
+// NewViewsFS works like NewViews but loads templates from an arbitrary
+// fs.FS instead of the OS filesystem. path is the directory inside fsys
+// to walk, relative to its root. This allows templates to be embedded
+// into the binary via //go:embed, served from a zip archive, or backed
+// by a virtual filesystem in tests.
+func NewViewsFS(fsys fs.FS, path, tmplExt string, reload bool) (*Views, error) {
+	return newViews(htmlMode, fsys, path, tmplExt, reload, nil, "", "")
+}
+
+// NewViewsWithOptions works like NewViews but additionally lets callers
+// register a template.FuncMap and custom left/right action delimiters.
+// Both are stored on the returned Views and re-applied on every reparse,
+// so they survive hot-reload. Pass "" for ldelim/rdelim to keep the
+// default "{{"/"}}" delimiters.
+func NewViewsWithOptions(path, tmplExt string, reload bool, funcs map[string]interface{}, ldelim, rdelim string) (*Views, error) {
+	return newViews(htmlMode, os.DirFS(path), ".", tmplExt, reload, funcs, ldelim, rdelim)
+}
+
+// NewViewsText works like NewViews, but parses templates in text/template
+// mode instead of html/template mode. Use this for rendering emails,
+// plain-text config files, generated code, or anything else where
+// html/template's contextual HTML escaping would corrupt the output.
+func NewViewsText(path, tmplExt string, reload bool) (*Views, error) {
+	return NewViewsTextFS(os.DirFS(path), ".", tmplExt, reload)
+}
+
+// NewViewsTextFS is the text/template counterpart of NewViewsFS.
+func NewViewsTextFS(fsys fs.FS, path, tmplExt string, reload bool) (*Views, error) {
+	return newViews(textMode, fsys, path, tmplExt, reload, nil, "", "")
+}
+
+// NewViewsTextWithOptions is the text/template counterpart of
+// NewViewsWithOptions.
+func NewViewsTextWithOptions(path, tmplExt string, reload bool, funcs map[string]interface{}, ldelim, rdelim string) (*Views, error) {
+	return newViews(textMode, os.DirFS(path), ".", tmplExt, reload, funcs, ldelim, rdelim)
+}
+
+func newViews(m mode, fsys fs.FS, path, tmplExt string, reload bool, funcs map[string]interface{}, ldelim, rdelim string) (*Views, error) {
 	v := &Views{
 		reload,
+		m,
+		fsys,
 		path,
 		tmplExt,
 		nil,
+		nil,
 		new(sync.Mutex),
+		funcs,
+		ldelim,
+		rdelim,
+		"layouts",
+		"partials",
+		nil,
+		nil,
+		nil,
 	}
 
 	err := v.ParseTemplates()
@@ -51,8 +127,7 @@ func NewViews(path, tmplExt string, reload bool) (*Views, error) {
 // ExecuteTemplate exposes the same API as {html,text}/template.Template.
 func (v *Views) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
 	if v.reload {
-		err := v.ParseTemplates()
-		if err != nil {
+		if err := v.syncTemplates(); err != nil {
 			return err
 		}
 	}
@@ -62,8 +137,7 @@ func (v *Views) ExecuteTemplate(w io.Writer, name string, data interface{}) erro
 // Execute exposes the same API as {html,text}/template.Template.
 func (v *Views) Execute(w io.Writer, data interface{}) error {
 	if v.reload {
-		err := v.ParseTemplates()
-		if err != nil {
+		if err := v.syncTemplates(); err != nil {
 			return err
 		}
 	}
@@ -78,29 +152,31 @@ func (v *Views) Reload(f bool) {
 	v.reload = f
 }
 
-// ParseTemplates parses all template files with the matching extension inside
-// the folder path.
+// ParseTemplates parses all template files with the matching extension
+// inside the folder path, discarding any per-file cache built up by
+// syncTemplates. Use this to force a full reparse; reload mode normally
+// drives itself through the cheaper syncTemplates instead.
+//
+// This is a two-pass operation: the first pass parses every file into a
+// throwaway template set to find its {{define "name param..."}} blocks
+// (see discoverTmplFuncs), which are registered as callable template
+// funcs, e.g. {{link .URL "click here"}} for a template defined as
+// {{define "link url text"}}. Go's template funcs must be known before
+// Parse is called, so the second pass reparses the same sources into a
+// fresh template set that already has those funcs registered.
+//
 // Save for concurrent use.
 func (v *Views) ParseTemplates() error {
-	t := template.New("all")
-
-	err := filepath.Walk(v.path, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			log.Println("Err while walking template dir: ", err)
-		}
-		if !info.IsDir() && filepath.Ext(path) == v.tmplExt {
-			b, err := ioutil.ReadFile(path)
-			if err != nil {
-				return err
-			}
-			t, err = t.New(v.TemplateName(path)).Parse(string(b))
-			if err != nil {
-				return err
-			}
-		}
+	files, err := v.readTemplateFiles()
+	if err != nil {
+		return err
+	}
 
+	t, fnNames, cache, err := buildTemplate(v, files)
+	if err != nil {
 		return err
-	})
+	}
+	pristine, err := t.Clone()
 	if err != nil {
 		return err
 	}
@@ -108,6 +184,9 @@ func (v *Views) ParseTemplates() error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	v.tmpls = t
+	v.pristine = pristine
+	v.cache = cache
+	v.fnNames = fnNames
 	return nil
 }
 
@@ -0,0 +1,41 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+)
+
+// TestNewViewsTextDoesNotEscape guards the core contract of text mode: a
+// NewViews(FS)-built Views HTML-escapes values through its "all" root
+// template, while a NewViewsText(FS)-built one passes them through as-is.
+func TestNewViewsTextDoesNotEscape(t *testing.T) {
+	fsys := fstest.MapFS{
+		"page.tmpl": {Data: []byte(`{{define "page"}}{{.}}{{end}}`)},
+	}
+	const data = `<script>`
+
+	html, err := NewViewsFS(fsys, ".", ".tmpl", false)
+	if err != nil {
+		t.Fatalf("NewViewsFS: %v", err)
+	}
+	var htmlBuf bytes.Buffer
+	if err := html.ExecuteTemplate(&htmlBuf, "page", data); err != nil {
+		t.Fatalf("ExecuteTemplate (html mode): %v", err)
+	}
+	if got, want := htmlBuf.String(), "&lt;script&gt;"; got != want {
+		t.Errorf("html mode output = %q, want %q", got, want)
+	}
+
+	text, err := NewViewsTextFS(fsys, ".", ".tmpl", false)
+	if err != nil {
+		t.Fatalf("NewViewsTextFS: %v", err)
+	}
+	var textBuf bytes.Buffer
+	if err := text.ExecuteTemplate(&textBuf, "page", data); err != nil {
+		t.Fatalf("ExecuteTemplate (text mode): %v", err)
+	}
+	if got, want := textBuf.String(), data; got != want {
+		t.Errorf("text mode output = %q, want %q (should not be escaped)", got, want)
+	}
+}
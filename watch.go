@@ -0,0 +1,93 @@
+package view
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fsnotifyWatcher is a package-local alias so the rest of the package can
+// reference the watcher type without importing fsnotify directly.
+type fsnotifyWatcher = fsnotify.Watcher
+
+// NewViewsWatch works like NewViews with reload disabled, but instead of
+// reparsing on every request (or stat-ing the tree to decide whether to,
+// see syncTemplates) it uses fsnotify to watch path for changes on disk and
+// triggers a full ParseTemplates only when something actually changes.
+// This is the cheapest hot-reload mode: ExecuteTemplate and Execute pay no
+// per-request filesystem cost at all.
+//
+// Call Close on the returned Views to stop watching.
+func NewViewsWatch(path, tmplExt string) (*Views, error) {
+	v, err := NewViews(path, tmplExt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := addDirsRecursive(w, path); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	v.watcher = w
+	go v.watchLoop()
+
+	return v, nil
+}
+
+func addDirsRecursive(w *fsnotifyWatcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return w.Add(path)
+		}
+		return nil
+	})
+}
+
+func (v *Views) watchLoop() {
+	for {
+		select {
+		case event, ok := <-v.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Create) != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := v.watcher.Add(event.Name); err != nil {
+						log.Println("view: failed to watch new directory:", err)
+					}
+				}
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if err := v.ParseTemplates(); err != nil {
+				log.Println("view: reparse after watch event failed:", err)
+			}
+		case err, ok := <-v.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Println("view: watch error:", err)
+		}
+	}
+}
+
+// Close stops the filesystem watcher started by NewViewsWatch. It is a
+// no-op for Views not created with NewViewsWatch.
+func (v *Views) Close() error {
+	if v.watcher == nil {
+		return nil
+	}
+	return v.watcher.Close()
+}